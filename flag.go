@@ -23,7 +23,17 @@ var (
 	fReader = flag.String(`in`, ``, `Source file for cleartext data (default stdin)`)
 	fWriter = flag.String(`out`, ``, `Destination file for hash results (default stdout)`)
 	fLogfile = flag.String(`log`, ``, `Destination file for log messages (default stderr)`)
-	fCost = flag.Int(`cost`, bcrypt.DefaultCost, `Bcrypt hash key expansion cost`)
 	fWorkers = flag.Int(`workers`, 10, `Number of concurrent worker routines`)
 	fQueue = flag.Int(`queue`, 1000, `Maximum length of worker input queues`)
+	fStatusInterval = flag.Int(`status-interval`, 5, `Seconds between throughput status log messages (0 = disabled)`)
+	fRate = flag.Float64(`rate`, 0, `Maximum hashes/sec across all workers (0 = unlimited)`)
+	fBurst = flag.Int(`burst`, 1, `Token bucket burst size for -rate`)
+
+	fMode = flag.String(`mode`, `hash`, `Operation mode: hash (generate) or verify (check against -passwords)`)
+	fPasswords = flag.String(`passwords`, ``, `Source file of candidate cleartext passwords (required in verify mode)`)
+	fHash = flag.String(`hash`, `bcrypt`, `Hash algorithm: bcrypt, ntlm, argon2id, scrypt, sha512crypt`)
+	fCost = flag.Int(`cost`, bcrypt.DefaultCost, `Bcrypt hash key expansion cost`)
+	fMemory = flag.Int(`memory`, 65536, `Memory cost in KiB (argon2id) or CPU/memory cost factor N (scrypt)`)
+	fIterations = flag.Int(`iterations`, 3, `Time cost (argon2id) or block size r (scrypt) or rounds (sha512crypt, 0 = algorithm default)`)
+	fParallelism = flag.Int(`parallelism`, 4, `Degree of parallelism: threads (argon2id) or p (scrypt)`)
 )
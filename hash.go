@@ -0,0 +1,59 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`fmt`
+)
+
+// Hasher generates and verifies password hashes for one algorithm.
+// Each supported algorithm implements Hasher and is looked up by
+// the name given to the -hash flag.
+type Hasher interface {
+
+	// Hash generates a hash of word.
+	Hash(word []byte) ([]byte, error)
+
+	// Verify reports whether word hashes to hash, dispatching on
+	// whatever parameters (cost, salt, rounds, ...) are encoded in
+	// hash itself rather than the current flag values.
+	Verify(hash, word []byte) (bool, error)
+}
+
+// hasherFactories maps a -hash flag value to a constructor that
+// builds the corresponding Hasher from the current flag values.
+// A constructor validates the flags relevant to its algorithm and
+// returns an error describing exactly what is wrong, rather than
+// the single hardcoded bcrypt range check this map replaces.
+var hasherFactories = map[string]func() (Hasher, error){
+	`bcrypt`:      newBcryptHasher,
+	`ntlm`:        newNTLMHasher,
+	`argon2id`:    newArgon2Hasher,
+	`scrypt`:      newScryptHasher,
+	`sha512crypt`: newSHA512CryptHasher,
+}
+
+// newHasher builds the Hasher registered under name, or returns an
+// error if name is not a supported algorithm or its flags are invalid.
+func newHasher(name string) (Hasher, error) {
+
+	factory, ok := hasherFactories[name]
+
+	if !ok {
+		return nil, fmt.Errorf(`unsupported hash algorithm '%s'`, name)
+	}
+
+	return factory()
+}
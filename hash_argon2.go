@@ -0,0 +1,118 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`crypto/rand`
+	`crypto/subtle`
+	`encoding/base64`
+	`fmt`
+	`strings`
+	`golang.org/x/crypto/argon2`
+)
+
+// argon2SaltLen and argon2KeyLen are the salt and derived key sizes
+// used for every argon2id hash, matching the sizes recommended by
+// the algorithm's authors.
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// argon2Hasher generates argon2id hashes at a fixed memory, time,
+// and parallelism cost, encoded in the standard PHC string format.
+type argon2Hasher struct {
+	memory      uint32
+	time        uint32
+	parallelism uint8
+}
+
+// newArgon2Hasher builds an argon2Hasher from -memory, -iterations,
+// and -parallelism.
+func newArgon2Hasher() (Hasher, error) {
+
+	if *fMemory <= 0 {
+		return nil, fmt.Errorf(`argon2id memory must be a positive number of KiB`)
+	}
+
+	if *fIterations <= 0 {
+		return nil, fmt.Errorf(`argon2id iterations (time cost) must be a positive integer`)
+	}
+
+	if *fParallelism <= 0 || *fParallelism > 255 {
+		return nil, fmt.Errorf(`argon2id parallelism must be between 1 and 255`)
+	}
+
+	return &argon2Hasher{
+		memory:      uint32(*fMemory),
+		time:        uint32(*fIterations),
+		parallelism: uint8(*fParallelism),
+	}, nil
+}
+
+// Hash generates a random salt and derives an argon2id key from
+// word, returning the result as a PHC-style encoded string.
+func (h *argon2Hasher) Hash(word []byte) ([]byte, error) {
+
+	salt := make([]byte, argon2SaltLen)
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey(word, salt, h.time, h.memory, h.parallelism, argon2KeyLen)
+
+	encoded := fmt.Sprintf(`$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s`,
+		argon2.Version, h.memory, h.time, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+
+	return []byte(encoded), nil
+}
+
+// Verify reports whether word is the cleartext behind hash. The
+// memory, time, parallelism, and salt are all read from hash
+// itself, not from -memory/-iterations/-parallelism.
+func (h *argon2Hasher) Verify(hash, word []byte) (bool, error) {
+
+	parts := strings.Split(string(hash), `$`)
+
+	if len(parts) != 6 || parts[1] != `argon2id` {
+		return false, fmt.Errorf(`not an argon2id hash: '%s'`, hash)
+	}
+
+	var memory, time uint32
+	var parallelism uint8
+
+	if _, err := fmt.Sscanf(parts[3], `m=%d,t=%d,p=%d`, &memory, &time, &parallelism); err != nil {
+		return false, fmt.Errorf(`invalid argon2id parameters: %w`, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+
+	if err != nil {
+		return false, err
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+
+	if err != nil {
+		return false, err
+	}
+
+	got := argon2.IDKey(word, salt, time, memory, parallelism, uint32(len(want)))
+
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`fmt`
+	`golang.org/x/crypto/bcrypt`
+)
+
+// bcryptHasher generates bcrypt hashes at a fixed key expansion cost.
+type bcryptHasher struct {
+	cost int
+}
+
+// newBcryptHasher builds a bcryptHasher from -cost, which must fall
+// within the range bcrypt itself supports.
+func newBcryptHasher() (Hasher, error) {
+
+	if *fCost < bcrypt.MinCost {
+		return nil, fmt.Errorf(`cost cannot be less than %d`, bcrypt.MinCost)
+	}
+
+	if *fCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf(`cost cannot be more than %d`, bcrypt.MaxCost)
+	}
+
+	return &bcryptHasher{cost: *fCost}, nil
+}
+
+// Hash generates a bcrypt hash of word at the configured cost.
+func (h *bcryptHasher) Hash(word []byte) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(word, h.cost)
+}
+
+// Verify reports whether word is the cleartext behind hash. The
+// cost is read from hash itself, not from -cost.
+func (h *bcryptHasher) Verify(hash, word []byte) (bool, error) {
+
+	switch err := bcrypt.CompareHashAndPassword(hash, word); err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	default:
+		return false, err
+	}
+}
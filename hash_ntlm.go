@@ -0,0 +1,75 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`encoding/binary`
+	`encoding/hex`
+	`strings`
+	`unicode/utf16`
+	`golang.org/x/crypto/md4`
+)
+
+// ntlmHasher generates NTLM hashes. NTLM has no cost parameter, so
+// unlike the other algorithms it carries no configuration.
+type ntlmHasher struct{}
+
+// newNTLMHasher builds an ntlmHasher. NTLM takes no flags, so there
+// is nothing to validate.
+func newNTLMHasher() (Hasher, error) {
+	return &ntlmHasher{}, nil
+}
+
+// Hash computes the NTLM hash of word: the password is UTF-16LE
+// encoded, run through MD4, and the digest is hex-encoded.
+func (h *ntlmHasher) Hash(word []byte) ([]byte, error) {
+
+	digest := md4.New()
+	digest.Write(utf16LEEncode(string(word)))
+	sum := digest.Sum(nil)
+
+	out := make([]byte, hex.EncodedLen(len(sum)))
+	hex.Encode(out, sum)
+
+	return out, nil
+}
+
+// Verify reports whether word is the cleartext behind hash. NTLM
+// has no cost parameter to recover, so this simply recomputes the
+// hash of word and compares it to hash.
+func (h *ntlmHasher) Verify(hash, word []byte) (bool, error) {
+
+	computed, err := h.Hash(word)
+
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(string(computed), string(hash)), nil
+}
+
+// utf16LEEncode encodes s as UTF-16, little-endian, the form NTLM
+// expects for its MD4 input.
+func utf16LEEncode(s string) []byte {
+
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, len(units)*2)
+
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(out[i*2:], unit)
+	}
+
+	return out
+}
@@ -0,0 +1,139 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`crypto/rand`
+	`crypto/subtle`
+	`encoding/base64`
+	`fmt`
+	`math/bits`
+	`strings`
+	`golang.org/x/crypto/scrypt`
+)
+
+// scryptSaltLen and scryptKeyLen are the salt and derived key sizes
+// used for every scrypt hash.
+const (
+	scryptSaltLen = 16
+	scryptKeyLen  = 32
+)
+
+// scryptHasher generates scrypt hashes at a fixed CPU/memory cost
+// (N), block size (r), and parallelism (p), encoded in the same
+// "$scrypt$ln=,r=,p=$salt$hash" style used by other scrypt tools.
+//
+// -memory supplies N, rounded up to the nearest power of two as
+// scrypt requires; -iterations supplies the block size r; and
+// -parallelism supplies p.
+type scryptHasher struct {
+	n int
+	r int
+	p int
+}
+
+// newScryptHasher builds a scryptHasher from -memory, -iterations,
+// and -parallelism.
+func newScryptHasher() (Hasher, error) {
+
+	if *fMemory <= 0 {
+		return nil, fmt.Errorf(`scrypt memory cost (N) must be a positive integer`)
+	}
+
+	if *fIterations <= 0 {
+		return nil, fmt.Errorf(`scrypt block size (r) must be a positive integer`)
+	}
+
+	if *fParallelism <= 0 {
+		return nil, fmt.Errorf(`scrypt parallelism (p) must be a positive integer`)
+	}
+
+	return &scryptHasher{
+		n: nextPowerOfTwo(*fMemory),
+		r: *fIterations,
+		p: *fParallelism,
+	}, nil
+}
+
+// nextPowerOfTwo returns the smallest power of two greater than or
+// equal to n, as scrypt's N parameter requires.
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 2
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// Hash generates a random salt and derives a scrypt key from word,
+// returning the result as a "$scrypt$ln=,r=,p=$salt$hash" string.
+func (h *scryptHasher) Hash(word []byte) ([]byte, error) {
+
+	salt := make([]byte, scryptSaltLen)
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(word, salt, h.n, h.r, h.p, scryptKeyLen)
+
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := fmt.Sprintf(`$scrypt$ln=%d,r=%d,p=%d$%s$%s`,
+		bits.Len(uint(h.n))-1, h.r, h.p,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+
+	return []byte(encoded), nil
+}
+
+// Verify reports whether word is the cleartext behind hash. N, r,
+// p, and the salt are all read from hash itself, not from
+// -memory/-iterations/-parallelism.
+func (h *scryptHasher) Verify(hash, word []byte) (bool, error) {
+
+	parts := strings.Split(string(hash), `$`)
+
+	if len(parts) != 5 || parts[1] != `scrypt` {
+		return false, fmt.Errorf(`not a scrypt hash: '%s'`, hash)
+	}
+
+	var ln, r, p int
+
+	if _, err := fmt.Sscanf(parts[2], `ln=%d,r=%d,p=%d`, &ln, &r, &p); err != nil {
+		return false, fmt.Errorf(`invalid scrypt parameters: %w`, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+
+	if err != nil {
+		return false, err
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[4])
+
+	if err != nil {
+		return false, err
+	}
+
+	got, err := scrypt.Key(word, salt, 1<<uint(ln), r, p, len(want))
+
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`sync`
+	`time`
+)
+
+// rateLimiter is a token-bucket rate limiter shared by every
+// hashing worker. It holds capacity tokens, refilled over time at
+// fillRate tokens/sec up to a maximum of capacity.
+type rateLimiter struct {
+	mu sync.Mutex
+	capacity float64
+	available float64
+	fillRate float64
+	last time.Time
+}
+
+// newRateLimiter builds a rateLimiter with the given burst capacity
+// and fill rate in tokens/sec, starting full.
+func newRateLimiter(capacity, fillRate float64) *rateLimiter {
+	return &rateLimiter{
+		capacity: capacity,
+		available: capacity,
+		fillRate: fillRate,
+		last: time.Now(),
+	}
+}
+
+// Wait blocks until n tokens are available, then deducts them. It
+// refills the bucket based on elapsed time before checking whether
+// n tokens are on hand, and sleeps for the shortfall if not.
+func (l *rateLimiter) Wait(n float64) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(l.last)
+	l.last = now
+
+	l.available += elapsed.Seconds() * l.fillRate
+	if l.available > l.capacity {
+		l.available = l.capacity
+	}
+
+	if l.available < n {
+		wait := time.Duration((n - l.available) / l.fillRate * float64(time.Second))
+		l.last = l.last.Add(wait)
+		time.Sleep(wait)
+		l.available = n
+	}
+
+	l.available -= n
+}
@@ -23,15 +23,19 @@ import (
 	`os`
 	`runtime`
 	`sync`
+	`sync/atomic`
 	`time`
-	`golang.org/x/crypto/bcrypt`
 )
 
 // The wait groups are used by the goroutines to notify the calling
-// routine when they are finished.
+// routine when they are finished. linesRead and hashesDone are
+// updated with sync/atomic since they are read by the monitor
+// goroutine while readWords and createHash are still running.
 var (
-	wgRead, wgHash, wgWrite sync.WaitGroup
-	count int
+	wgRead, wgHash, wgWrite, wgMonitor sync.WaitGroup
+	linesRead, hashesDone int64
+	hasher Hasher
+	limiter *rateLimiter
 )
 
 // init parses the command line flags and performs sanity checks.
@@ -47,15 +51,25 @@ func init() {
 		}
 	}
 
-	if *fCost < bcrypt.MinCost {
-		log.Fatalf(`Cost cannot be less than %s`, bcrypt.MinCost)
+	if *fMode != `hash` && *fMode != `verify` {
+		log.Fatalf(`Unsupported mode '%s'; use 'hash' or 'verify'.`, *fMode)
 	}
 
-	if *fCost > bcrypt.MaxCost {
-		log.Fatalf(`Cost cannot be more than %s`, bcrypt.MaxCost)
+	if *fMode == `verify` && *fPasswords == `` {
+		log.Fatal(`Verify mode requires -passwords.`)
 	}
 
-	log.Printf(`Generating hashes with key expansion cost of %d.`, *fCost)
+	var err error
+
+	if hasher, err = newHasher(*fHash); err != nil {
+		log.Fatal(err)
+	}
+
+	if *fMode == `verify` {
+		log.Printf(`Verifying %s hashes against candidate passwords.`, *fHash)
+	} else {
+		log.Printf(`Generating %s hashes.`, *fHash)
+	}
 
 	if *fWorkers <= 0 {
 		log.Fatal(`Number of workers must be a positive integer.`)
@@ -68,6 +82,23 @@ func init() {
 	}
 
 	log.Printf(`Using a queue size of %d for worker input.`, *fQueue)
+
+	if *fStatusInterval < 0 {
+		log.Fatal(`Status interval cannot be negative.`)
+	}
+
+	if *fRate < 0 {
+		log.Fatal(`Rate cannot be negative.`)
+	}
+
+	if *fBurst <= 0 {
+		log.Fatal(`Burst size must be a positive integer.`)
+	}
+
+	if *fRate > 0 {
+		limiter = newRateLimiter(float64(*fBurst), *fRate)
+		log.Printf(`Limiting hash generation to %.1f hashes/sec, burst %d.`, *fRate, *fBurst)
+	}
 }
 
 // readWords takes an io.Reader input and a []byte channel for output.
@@ -82,7 +113,7 @@ func readWords(reader io.Reader, words chan<- []byte) {
 
 	for scanner.Scan() {
 		words<- []byte(scanner.Text())
-		count++
+		atomic.AddInt64(&linesRead, 1)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -90,13 +121,13 @@ func readWords(reader io.Reader, words chan<- []byte) {
 	}
 }
 
-// createHash takes a bcrypt cost factor (the two's exponent that 
-// defines the number of key expansion rounds) a []byte channel for
-// input and a []byte channel for output. It reads lines from the
-// input channel, generates a bcrypt hash on the line, and queues
-// the result on the output channel. It exits when it encounters
-// the end of the input channel.
-func createHash(cost int, words <-chan []byte, results chan<- []byte) {
+// createHash takes a []byte channel for input and a []byte channel
+// for output. In hash mode it generates a hash of each line using
+// the configured Hasher; in verify mode it checks each "user:hash"
+// line against the candidate passwords instead. Either way it
+// queues one result line per input line on the output channel,
+// and exits when it encounters the end of the input channel.
+func createHash(words <-chan []byte, results chan<- []byte) {
 
 	defer wgHash.Done()
 
@@ -104,11 +135,21 @@ func createHash(cost int, words <-chan []byte, results chan<- []byte) {
 		word, ok := <-words
 		if !ok { break }
 
-		hash, err := bcrypt.GenerateFromPassword(word, cost)
+		if limiter != nil {
+			limiter.Wait(1)
+		}
+
+		if *fMode == `verify` {
+			results<- verifyRecord(word)
+			continue
+		}
+
+		hash, err := hasher.Hash(word)
 
 		if err != nil {
 			log.Print(err)
 		} else {
+			atomic.AddInt64(&hashesDone, 1)
 			results<- []byte(fmt.Sprintf("%s:%s\n", word, hash))
 		}
 	}
@@ -168,17 +209,55 @@ func main() {
 
 	bufwriter := bufio.NewWriter(writer)
 
+	// In verify mode, load the candidate passwords once up front;
+	// every worker checks the same list against its own record.
+
+	if *fMode == `verify` {
+		var err error
+
+		if passwords, err = loadPasswords(*fPasswords); err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf(`Loaded %d candidate passwords.`, len(passwords))
+	}
+
 	// Create buffered channels for input lines and hash results.
 
 	words := make(chan []byte, *fQueue)
 	results := make(chan []byte, *fQueue)
 
+	// If the input is a regular, seekable file, pre-count its lines
+	// so the monitor can report an ETA, then rewind it for readWords.
+	// Stdin and pipes implement io.Seeker but fail when seeked, so
+	// an error here is not fatal -- it just leaves the ETA disabled.
+
+	var totalLines int64
+
+	if seeker, ok := reader.(io.Seeker); ok && *fReader != `` {
+		n := countLines(reader)
+
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			log.Print(err)
+		} else {
+			totalLines = n
+			log.Printf(`Input contains %d lines.`, totalLines)
+		}
+	}
+
 	// Start a timer and log processing time.
 
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
-		log.Printf(`Processed %d records in %s`, count, elapsed)
+
+		if *fMode == `verify` {
+			log.Printf(`Verified %d records in %s: %d/%d matched.`, atomic.LoadInt64(&linesRead), elapsed,
+				atomic.LoadInt64(&verifyMatches), atomic.LoadInt64(&verifyAttempts))
+		} else {
+			avg := float64(atomic.LoadInt64(&hashesDone)) / elapsed.Seconds()
+			log.Printf(`Processed %d records in %s (%.1f hashes/sec average).`, atomic.LoadInt64(&linesRead), elapsed, avg)
+		}
 	}()
 
 	// Spawn the input goroutine.
@@ -190,7 +269,7 @@ func main() {
 
 	wgHash.Add(*fWorkers)
 	for i := 1; i <= *fWorkers; i++ {
-		go createHash(*fCost, words, results)
+		go createHash(words, results)
 	}
 
 	log.Printf(`Total active goroutines: %d.`, runtime.NumGoroutine())
@@ -199,6 +278,16 @@ func main() {
 	wgWrite.Add(1)
 	go writeReport(bufwriter, results)
 
+	// Spawn the monitor goroutine, which logs throughput statistics
+	// until the hashing goroutines finish.
+
+	monitorDone := make(chan struct{})
+
+	if *fStatusInterval > 0 {
+		wgMonitor.Add(1)
+		go monitor(words, results, totalLines, monitorDone)
+	}
+
 	// wait for the reader and hashing goroutines to finish, then
 	// close their associated output channels.
 
@@ -207,6 +296,8 @@ func main() {
 
 	wgHash.Wait()
 	close(results)
+	close(monitorDone)
+	wgMonitor.Wait()
 
 	// Wait for the writer goroutine to finish, then flush the
 	// buffered writer and exit.
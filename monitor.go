@@ -0,0 +1,96 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`bufio`
+	`fmt`
+	`io`
+	`log`
+	`sync/atomic`
+	`time`
+)
+
+// monitor logs throughput statistics every -status-interval seconds:
+// records completed since the last tick, the resulting rate, and
+// the depth of the input and output queues. In hash mode it tracks
+// hashesDone; in verify mode it tracks verifyAttempts instead, so
+// an audit run is not blind just because it never calls Hash. If
+// total is greater than zero (the input was a seekable file whose
+// line count is known), it also logs an ETA based on the current
+// rate. It exits when done is closed.
+func monitor(words <-chan []byte, results <-chan []byte, total int64, done <-chan struct{}) {
+
+	defer wgMonitor.Done()
+
+	interval := time.Duration(*fStatusInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	noun := `hashes done`
+	counter := &hashesDone
+
+	if *fMode == `verify` {
+		noun = `records checked`
+		counter = &verifyAttempts
+	}
+
+	var last int64
+
+	for {
+		select {
+		case <-ticker.C:
+
+			completed := atomic.LoadInt64(counter)
+			rate := float64(completed-last) / interval.Seconds()
+			last = completed
+
+			msg := fmt.Sprintf(`Status: %d %s, %.1f/sec, input queue %d, output queue %d`,
+				completed, noun, rate, len(words), len(results))
+
+			if total > 0 {
+				if remaining := total - completed; remaining > 0 && rate > 0 {
+					eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+					msg += fmt.Sprintf(`, ETA %s`, eta.Round(time.Second))
+				}
+			}
+
+			log.Print(msg)
+
+		case <-done:
+			return
+		}
+	}
+}
+
+// countLines scans reader and returns the number of lines it
+// contains, without regard to their content. It is used to size
+// the monitor's ETA when the input is a seekable file.
+func countLines(reader io.Reader) int64 {
+
+	scanner := bufio.NewScanner(reader)
+
+	var n int64
+
+	for scanner.Scan() {
+		n++
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Print(err)
+	}
+
+	return n
+}
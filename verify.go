@@ -0,0 +1,96 @@
+// Copyright 2017 John Scherff
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	`bufio`
+	`bytes`
+	`fmt`
+	`log`
+	`os`
+	`sync/atomic`
+)
+
+// passwords holds the candidate cleartexts loaded from -passwords.
+// It is populated once in main before the hashing goroutines start,
+// and only ever read afterward, so it needs no further locking.
+var passwords [][]byte
+
+// verifyAttempts and verifyMatches count, across all workers, how
+// many "user:hash" records were checked and how many of them
+// matched one of the candidate passwords.
+var verifyAttempts, verifyMatches int64
+
+// loadPasswords reads one candidate password per line from path.
+func loadPasswords(path string) ([][]byte, error) {
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer fh.Close()
+
+	var list [][]byte
+	scanner := bufio.NewScanner(fh)
+
+	for scanner.Scan() {
+		list = append(list, []byte(scanner.Text()))
+	}
+
+	return list, scanner.Err()
+}
+
+// verifyRecord takes a "user:hash" line (or a bare "hash" line,
+// with the user field left empty) and tries each candidate
+// password in passwords against it with the configured Hasher. It
+// returns a "user:password:MATCH|NOMATCH" result line, reporting
+// the first candidate that matches or NOMATCH if none did.
+func verifyRecord(line []byte) []byte {
+
+	atomic.AddInt64(&verifyAttempts, 1)
+
+	user, hash := splitUserHash(line)
+
+	for _, password := range passwords {
+
+		ok, err := hasher.Verify(hash, password)
+
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		if ok {
+			atomic.AddInt64(&verifyMatches, 1)
+			return []byte(fmt.Sprintf("%s:%s:MATCH\n", user, password))
+		}
+	}
+
+	return []byte(fmt.Sprintf("%s::NOMATCH\n", user))
+}
+
+// splitUserHash splits line on its first colon into a user and a
+// hash field. If line has no colon, it is treated as a bare hash
+// and the user field is returned empty.
+func splitUserHash(line []byte) (user, hash []byte) {
+
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		return line[:i], line[i+1:]
+	}
+
+	return nil, line
+}